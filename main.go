@@ -1,299 +1,232 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/KittenzExe/scoresaberwithoutaquaflee/ranker"
+	"github.com/KittenzExe/scoresaberwithoutaquaflee/store"
 )
 
-type PlayersResponse struct {
-	Players []Player `json:"players"`
-}
+// Server holds the latest recomputed rankings in memory and refreshes them
+// on a fixed interval.
+type Server struct {
+	rk           *ranker.Ranker
+	db           *store.Store
+	fetchTimeout time.Duration
 
-type Player struct {
-	ID             string      `json:"id"`
-	Name           string      `json:"name"`
-	ProfilePicture string      `json:"profilePicture"`
-	Bio            *string     `json:"bio"`
-	Country        string      `json:"country"`
-	PP             float64     `json:"pp"`
-	Rank           int         `json:"rank"`
-	CountryRank    int         `json:"countryRank"`
-	Role           *string     `json:"role"`
-	Badges         interface{} `json:"badges"`
-	Histories      string      `json:"histories"`
-	Permissions    int         `json:"permissions"`
-	Banned         bool        `json:"banned"`
-	Inactive       bool        `json:"inactive"`
-	ScoreStats     ScoreStats  `json:"scoreStats"`
-	FirstSeen      string      `json:"firstSeen"`
+	mu            sync.RWMutex
+	results       []ranker.PlayerResult
+	lastRecompute time.Time
 }
 
-type ScoreStats struct {
-	TotalScore            int     `json:"totalScore"`
-	TotalRankedScore      int     `json:"totalRankedScore"`
-	AverageRankedAccuracy float64 `json:"averageRankedAccuracy"`
-	TotalPlayCount        int     `json:"totalPlayCount"`
-	RankedPlayCount       int     `json:"rankedPlayCount"`
-	ReplaysWatched        int     `json:"replaysWatched"`
+func NewServer(rk *ranker.Ranker, db *store.Store, fetchTimeout time.Duration) *Server {
+	return &Server{rk: rk, db: db, fetchTimeout: fetchTimeout}
 }
 
-type PlayerScores struct {
-	PlayerScores []PlayerScore `json:"playerScores"`
-}
+func (s *Server) recompute() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.fetchTimeout)
+	defer cancel()
+
+	results, err := s.rk.Recompute(ctx)
+	if err != nil {
+		slog.Error("recompute failed", "error", err)
+		return
+	}
 
-type PlayerScore struct {
-	Score       Score       `json:"score"`
-	Leaderboard Leaderboard `json:"leaderboard"`
+	takenAt := s.rk.Clock.Now()
+	if _, err := s.db.SaveSnapshot(ctx, takenAt, results); err != nil {
+		slog.Error("saving snapshot failed", "error", err)
+	}
+
+	s.mu.Lock()
+	s.results = results
+	s.lastRecompute = takenAt
+	s.mu.Unlock()
+
+	slog.Info("recompute cycle complete", "players", len(results))
 }
 
-type Score struct {
-	ID                    int     `json:"id"`
-	LeaderboardPlayerInfo *string `json:"leaderboardPlayerInfo"`
-	Rank                  int     `json:"rank"`
-	BaseScore             int     `json:"baseScore"`
-	ModifiedScore         int     `json:"modifiedScore"`
-	PP                    float64 `json:"pp"`
-	Weight                float64 `json:"weight"`
-	Modifiers             string  `json:"modifiers"`
-	Multiplier            float64 `json:"multiplier"`
-	BadCuts               int     `json:"badCuts"`
-	MissedNotes           int     `json:"missedNotes"`
-	MaxCombo              int     `json:"maxCombo"`
-	FullCombo             bool    `json:"fullCombo"`
-	Hmd                   int     `json:"hmd"`
-	TimeSet               string  `json:"timeSet"`
-	HasReplay             bool    `json:"hasReplay"`
-	DeviceHmd             string  `json:"deviceHmd"`
-	DeviceControllerLeft  string  `json:"deviceControllerLeft"`
-	DeviceControllerRight string  `json:"deviceControllerRight"`
+// run recomputes immediately, then on every tick of interval until the
+// process exits.
+func (s *Server) run(interval time.Duration) {
+	s.recompute()
+
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		s.recompute()
+	}
 }
 
-type Leaderboard struct {
-	ID                int        `json:"id"`
-	SongHash          string     `json:"songHash"`
-	SongName          string     `json:"songName"`
-	SongSubName       string     `json:"songSubName"`
-	SongAuthorName    string     `json:"songAuthorName"`
-	LevelAuthorName   string     `json:"levelAuthorName"`
-	Difficulty        Difficulty `json:"difficulty"`
-	MaxScore          int        `json:"maxScore"`
-	CreatedDate       string     `json:"createdDate"`
-	RankedDate        *string    `json:"rankedDate"`
-	QualifiedDate     *string    `json:"qualifiedDate"`
-	LovedDate         *string    `json:"lovedDate"`
-	Ranked            bool       `json:"ranked"`
-	Qualified         bool       `json:"qualified"`
-	Loved             bool       `json:"loved"`
-	MaxPP             float64    `json:"maxPP"`
-	Stars             float64    `json:"stars"`
-	Plays             int        `json:"plays"`
-	DailyPlays        int        `json:"dailyPlays"`
-	PositiveModifiers bool       `json:"positiveModifiers"`
-	PlayerScore       *string    `json:"playerScore"`
-	CoverImage        string     `json:"coverImage"`
-	Difficulties      *string    `json:"difficulties"`
+func (s *Server) snapshot() ([]ranker.PlayerResult, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.results, s.lastRecompute
 }
 
-type Difficulty struct {
-	LeaderboardID int    `json:"leaderboardId"`
-	Difficulty    int    `json:"difficulty"`
-	GameMode      string `json:"gameMode"`
-	DifficultyRaw string `json:"difficultyRaw"`
+func (s *Server) seed() string {
+	_, lastRecompute := s.snapshot()
+	return strconv.FormatInt(lastRecompute.UnixNano(), 36)
 }
 
-type PlayerResult struct {
-	Player         Player
-	OriginalRank   int
-	TotalPP        float64
-	TotalScores    int
-	AquafleeScores int
-	ValidScores    int
-	PPDifference   float64
+func writeJSON(w http.ResponseWriter, lastRecompute time.Time, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", fmt.Sprintf("%q", strconv.FormatInt(lastRecompute.UnixNano(), 36)))
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("writeJSON failed", "error", err)
+	}
 }
 
-func fetchPlayerScores(playerID string) (float64, int, int, error) {
-	url := fmt.Sprintf("https://scoresaber.com/api/player/%s/scores?limit=100", playerID)
+func (s *Server) handleRankings(w http.ResponseWriter, r *http.Request) {
+	results, lastRecompute := s.snapshot()
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, 0, 0, err
+	limit := 50
+	offset := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limit = n
+		}
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, 0, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, 0, 0, err
+	if offset > len(results) {
+		offset = len(results)
 	}
-
-	var playerScores PlayerScores
-	err = json.Unmarshal(body, &playerScores)
-	if err != nil {
-		return 0, 0, 0, err
+	end := offset + limit
+	if end > len(results) {
+		end = len(results)
 	}
 
-	totalPP := 0.0
-	totalScores := len(playerScores.PlayerScores)
-	aquafleeScores := 0
+	writeJSON(w, lastRecompute, results[offset:end])
+}
 
-	for _, playerScore := range playerScores.PlayerScores {
-		levelAuthor := strings.ToLower(playerScore.Leaderboard.LevelAuthorName)
-		if strings.Contains(levelAuthor, "aquaflee") {
-			aquafleeScores++
-			continue
-		}
+func (s *Server) handlePlayer(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/player/")
+	if id == "" {
+		http.Error(w, "missing player id", http.StatusBadRequest)
+		return
+	}
 
-		pp := playerScore.Score.PP
-		weight := playerScore.Score.Weight
-		weightedPP := pp * weight
-		totalPP += weightedPP
+	results, lastRecompute := s.snapshot()
+	for _, result := range results {
+		if result.Player.ID == id {
+			writeJSON(w, lastRecompute, result)
+			return
+		}
 	}
 
-	return totalPP, totalScores, aquafleeScores, nil
+	http.Error(w, "player not found", http.StatusNotFound)
 }
 
-func main() {
-	// Fetch top 10 players
-	playersURL := "https://scoresaber.com/api/players"
-
-	resp, err := http.Get(playersURL)
-	if err != nil {
-		log.Fatalf("Error fetching players: %v", err)
-	}
-	defer resp.Body.Close()
+func (s *Server) handleSeed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"seed":%q}`, s.seed())
+}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Fatalf("Players API request failed with status: %d", resp.StatusCode)
-	}
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"status":"ok"}`)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading players response: %v", err)
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/history/")
+	if id == "" {
+		http.Error(w, "missing player id", http.StatusBadRequest)
+		return
 	}
 
-	var playersResponse PlayersResponse
-	err = json.Unmarshal(body, &playersResponse)
-	if err != nil {
-		log.Fatalf("Error parsing players JSON: %v", err)
+	since := 7 * 24 * time.Hour
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := parseSince(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+			return
+		}
+		since = d
 	}
 
-	file, err := os.Create("top_players.json")
+	points, err := s.db.History(r.Context(), id, s.rk.Clock.Now().Add(-since))
 	if err != nil {
-		log.Fatalf("Error creating players file: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	err = encoder.Encode(playersResponse)
-	if err != nil {
-		log.Fatalf("Error writing players to file: %v", err)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(points); err != nil {
+		log.Printf("writeJSON: %v", err)
 	}
+}
 
-	fmt.Println("\nTop 10 Players - Calculated Total PP from 100 Scores (Excluding Aquaflee Maps):")
-
-	var results []PlayerResult
-
-	for i, player := range playersResponse.Players {
-		if i >= 50 {
-			break
-		}
-
-		fmt.Printf("\nFetching scores for Rank #%d: %s (ID: %s)...\n", player.Rank, player.Name, player.ID)
-
-		totalPP, totalScores, aquafleeScores, err := fetchPlayerScores(player.ID)
+// parseSince parses a duration like "7d", "24h", or "30m". time.ParseDuration
+// already understands everything but the "d" (day) unit.
+func parseSince(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
 		if err != nil {
-			fmt.Printf("Error fetching scores for %s: %v\n", player.Name, err)
-			continue
-		}
-
-		validScores := totalScores - aquafleeScores
-		ppDifference := player.PP - totalPP
-
-		result := PlayerResult{
-			Player:         player,
-			OriginalRank:   player.Rank,
-			TotalPP:        totalPP,
-			TotalScores:    totalScores,
-			AquafleeScores: aquafleeScores,
-			ValidScores:    validScores,
-			PPDifference:   ppDifference,
+			return 0, err
 		}
-		results = append(results, result)
-
-		fmt.Printf("Rank #%d: %s\n", player.Rank, player.Name)
-		fmt.Printf("  - Player ID: %s\n", player.ID)
-		fmt.Printf("  - Official PP: %.2f\n", player.PP)
-		fmt.Printf("  - Total Scores: %d\n", totalScores)
-		fmt.Printf("  - Aquaflee Scores Removed: %d\n", aquafleeScores)
-		fmt.Printf("  - Valid Scores Used: %d\n", validScores)
-		fmt.Printf("  - Calculated Total PP (without Aquaflee): %.4f\n", totalPP)
-		fmt.Printf("  - PP Difference: %.4f\n", ppDifference)
-
-		time.Sleep(100 * time.Millisecond)
+		return time.Duration(days) * 24 * time.Hour, nil
 	}
+	return time.ParseDuration(s)
+}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].TotalPP > results[j].TotalPP
-	})
-
-	fmt.Println("NEW TOP 50 RANKING (Based on PP without Aquaflee Maps):")
-
-	for i, result := range results {
-		rankChange := result.OriginalRank - (i + 1)
-		rankChangeStr := ""
-		if rankChange > 0 {
-			rankChangeStr = fmt.Sprintf(" (↑%d)", rankChange)
-		} else if rankChange < 0 {
-			rankChangeStr = fmt.Sprintf(" (↓%d)", -rankChange)
-		} else {
-			rankChangeStr = " (=)"
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	refresh := flag.Duration("refresh", 15*time.Minute, "interval between rankings recomputes")
+	limit := flag.Int("limit", 50, "number of top players to recompute")
+	workers := flag.Int("workers", 8, "number of concurrent workers fetching player scores")
+	fetchTimeout := flag.Duration("fetch-timeout", 30*time.Second, "timeout for a full recompute cycle")
+	filtersPath := flag.String("filters", "", "path to a YAML or JSON filter config (defaults to excluding aquaflee maps)")
+	dbPath := flag.String("db", "./scoresaber.db", "path to the SQLite history database")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	rk := ranker.New()
+	rk.Limit = *limit
+	rk.Workers = *workers
+
+	if *filtersPath != "" {
+		filters, err := ranker.LoadFilters(*filtersPath)
+		if err != nil {
+			log.Fatalf("loading filters: %v", err)
 		}
-
-		fmt.Printf("\n#%d: %s%s\n", i+1, result.Player.Name, rankChangeStr)
-		fmt.Printf("    Original Rank: #%d\n", result.OriginalRank)
-		fmt.Printf("    Official PP: %.2f\n", result.Player.PP)
-		fmt.Printf("    PP without Aquaflee: %.4f\n", result.TotalPP)
-		fmt.Printf("    PP Lost to Aquaflee: %.4f (%.2f%%)\n",
-			result.PPDifference,
-			(result.PPDifference/result.Player.PP)*100)
-		fmt.Printf("    Aquaflee Scores: %d/%d\n", result.AquafleeScores, result.TotalScores)
+		rk.Filters = filters
 	}
 
-	fmt.Println("RANKING CHANGES SUMMARY:")
-
-	for i, result := range results {
-		newRank := i + 1
-		rankChange := result.OriginalRank - newRank
-
-		if rankChange != 0 {
-			direction := "down"
-			if rankChange > 0 {
-				direction = "up"
-			}
-			fmt.Printf("%s: #%d → #%d (moved %s %d positions)\n",
-				result.Player.Name, result.OriginalRank, newRank, direction, abs(rankChange))
-		} else {
-			fmt.Printf("%s: #%d (no change)\n", result.Player.Name, result.OriginalRank)
-		}
+	db, err := store.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("opening store: %v", err)
 	}
+	defer db.Close()
 
-	fmt.Println("\nAnalysis complete!")
-}
+	srv := NewServer(rk, db, *fetchTimeout)
+	go srv.run(*refresh)
 
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rankings", srv.handleRankings)
+	mux.HandleFunc("/player/", srv.handlePlayer)
+	mux.HandleFunc("/history/", srv.handleHistory)
+	mux.HandleFunc("/seed", srv.handleSeed)
+	mux.HandleFunc("/healthz", srv.handleHealthz)
+	mux.Handle("/metrics", promhttp.Handler())
+
+	slog.Info("listening", "addr", *addr, "refresh", refresh.String(), "limit", *limit)
+	log.Fatal(http.ListenAndServe(*addr, mux))
 }
@@ -0,0 +1,46 @@
+// Package metrics holds the Prometheus collectors shared across the fetch
+// and recompute pipeline.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// FetchRequestsTotal counts outbound ScoreSaber API requests by
+	// response status ("200", "429", "error", ...).
+	FetchRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scoresaber_fetch_requests_total",
+		Help: "Total number of ScoreSaber API requests, by response status.",
+	}, []string{"status"})
+
+	// FetchDurationSeconds observes the latency of individual ScoreSaber
+	// API requests.
+	FetchDurationSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scoresaber_fetch_duration_seconds",
+		Help:    "Duration of individual ScoreSaber API requests.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// FilteredScoresTotal counts scores removed by each active filter.
+	FilteredScoresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scoresaber_filtered_scores_total",
+		Help: "Total number of scores removed, by filter name.",
+	}, []string{"filter"})
+
+	// RecomputeDurationSeconds observes the latency of a full rankings
+	// recompute cycle.
+	RecomputeDurationSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "scoresaber_recompute_duration_seconds",
+		Help:    "Duration of a full rankings recompute cycle.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// PlayerPPDelta tracks the gap between a player's official PP and their
+	// recomputed PP, by player ID.
+	PlayerPPDelta = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scoresaber_player_pp_delta",
+		Help: "Difference between a player's official PP and their recomputed PP.",
+	}, []string{"player_id"})
+)
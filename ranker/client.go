@@ -0,0 +1,160 @@
+package ranker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/KittenzExe/scoresaberwithoutaquaflee/metrics"
+)
+
+// HTTPClient is the subset of *http.Client used by this package, so tests
+// can substitute a fake transport via httptest.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Clock abstracts time so recompute timestamps are testable.
+type Clock interface {
+	Now() time.Time
+}
+
+const DefaultBaseURL = "https://scoresaber.com"
+
+// fetchJSON performs an HTTP GET and decodes the JSON response into out,
+// returning the response status code (0 if the request never got a
+// response). If limiter is non-nil, it waits for a token before issuing the
+// request, so every outbound request (including each page of a multi-page
+// fetch) is individually rate-limited.
+func fetchJSON(ctx context.Context, client HTTPClient, limiter *rate.Limiter, url string, out interface{}) (statusCode int, err error) {
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	start := time.Now()
+	metricStatus := "error"
+	defer func() {
+		metrics.FetchDurationSeconds.Observe(time.Since(start).Seconds())
+		metrics.FetchRequestsTotal.WithLabelValues(metricStatus).Inc()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	statusCode = resp.StatusCode
+	metricStatus = strconv.Itoa(statusCode)
+	if statusCode != http.StatusOK {
+		return statusCode, fmt.Errorf("request to %s failed with status: %d", url, statusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return statusCode, err
+	}
+
+	return statusCode, json.Unmarshal(body, out)
+}
+
+// FetchPlayers retrieves the current ScoreSaber player leaderboard.
+func FetchPlayers(ctx context.Context, client HTTPClient, limiter *rate.Limiter, baseURL string) (PlayersResponse, error) {
+	var players PlayersResponse
+	url := fmt.Sprintf("%s/api/players", baseURL)
+	if _, err := fetchJSON(ctx, client, limiter, url, &players); err != nil {
+		return PlayersResponse{}, err
+	}
+	return players, nil
+}
+
+// scoresPageSize is the maximum number of scores the ScoreSaber API returns
+// per page.
+const scoresPageSize = 100
+
+// fetchAllPlayerScores retrieves every page of a player's scores, stopping
+// once a page comes back with fewer entries than scoresPageSize. Each page
+// is its own outbound request and is gated by limiter individually. It
+// returns the status code of the last request made.
+func fetchAllPlayerScores(ctx context.Context, client HTTPClient, limiter *rate.Limiter, baseURL, playerID string) ([]PlayerScore, int, error) {
+	var all []PlayerScore
+	var statusCode int
+
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/api/player/%s/scores?limit=%d&page=%d", baseURL, playerID, scoresPageSize, page)
+
+		var playerScores PlayerScores
+		status, err := fetchJSON(ctx, client, limiter, url, &playerScores)
+		statusCode = status
+		if err != nil {
+			return nil, statusCode, err
+		}
+
+		all = append(all, playerScores.PlayerScores...)
+		if len(playerScores.PlayerScores) < scoresPageSize {
+			break
+		}
+	}
+
+	return all, statusCode, nil
+}
+
+// ScoreFetchResult is the outcome of recomputing a single player's PP.
+// Scores holds every score fetched for the player (unfiltered), so callers
+// can persist the latest PP seen per leaderboard.
+type ScoreFetchResult struct {
+	TotalPP        float64
+	TotalScores    int
+	RemovedScores  int
+	FilteredScores map[string]int
+	Scores         []PlayerScore
+	HTTPStatus     int
+}
+
+// FetchPlayerScores retrieves every page of a player's scores and recomputes
+// their total PP using RecomputePP, re-weighting the survivors with
+// ScoreSaber's decay curve rather than trusting the API's per-score
+// "weight" field. FilteredScores records, per filter name, how many scores
+// that filter removed; RemovedScores is the number of distinct scores
+// removed by at least one filter. A failure is wrapped with the player ID
+// so callers can identify which player to retry.
+func FetchPlayerScores(ctx context.Context, client HTTPClient, limiter *rate.Limiter, baseURL, playerID string, filters []Filter) (ScoreFetchResult, error) {
+	scores, statusCode, err := fetchAllPlayerScores(ctx, client, limiter, baseURL, playerID)
+	if err != nil {
+		return ScoreFetchResult{HTTPStatus: statusCode}, fmt.Errorf("player %s: %w", playerID, err)
+	}
+
+	filteredScores := make(map[string]int)
+	for _, score := range scores {
+		for _, f := range filters {
+			if f.Match(score) {
+				filteredScores[f.Name()]++
+				metrics.FilteredScoresTotal.WithLabelValues(f.Name()).Inc()
+			}
+		}
+	}
+
+	totalPP, _, removedScores := RecomputePP(scores, filters)
+
+	return ScoreFetchResult{
+		TotalPP:        totalPP,
+		TotalScores:    len(scores),
+		RemovedScores:  removedScores,
+		FilteredScores: filteredScores,
+		Scores:         scores,
+		HTTPStatus:     statusCode,
+	}, nil
+}
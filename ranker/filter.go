@@ -0,0 +1,130 @@
+package ranker
+
+import "strings"
+
+// Filter decides whether a score should be excluded from a player's PP
+// recomputation. Match reports whether the score matches the filter's
+// criteria and should therefore be removed.
+type Filter interface {
+	Match(PlayerScore) bool
+	Name() string
+}
+
+// MapperNameFilter matches scores on maps by a banned level author.
+// Matching is a case-insensitive substring match, same as the original
+// hardcoded "aquaflee" check.
+type MapperNameFilter struct {
+	Names []string
+}
+
+func (f MapperNameFilter) Name() string { return "mapperName" }
+
+func (f MapperNameFilter) Match(ps PlayerScore) bool {
+	author := strings.ToLower(ps.Leaderboard.LevelAuthorName)
+	for _, name := range f.Names {
+		if strings.Contains(author, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SongHashFilter matches scores on specific leaderboard song hashes.
+type SongHashFilter struct {
+	Hashes []string
+}
+
+func (f SongHashFilter) Name() string { return "songHash" }
+
+func (f SongHashFilter) Match(ps PlayerScore) bool {
+	for _, hash := range f.Hashes {
+		if strings.EqualFold(ps.Leaderboard.SongHash, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// DifficultyFilter matches scores on a named difficulty, e.g. "ExpertPlus".
+type DifficultyFilter struct {
+	Difficulties []string
+}
+
+func (f DifficultyFilter) Name() string { return "difficulty" }
+
+func (f DifficultyFilter) Match(ps PlayerScore) bool {
+	for _, d := range f.Difficulties {
+		if strings.EqualFold(ps.Leaderboard.Difficulty.DifficultyRaw, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// StarRangeFilter matches scores whose leaderboard star rating falls within
+// [Min, Max], inclusive.
+type StarRangeFilter struct {
+	Min float64
+	Max float64
+}
+
+func (f StarRangeFilter) Name() string { return "starRange" }
+
+func (f StarRangeFilter) Match(ps PlayerScore) bool {
+	stars := ps.Leaderboard.Stars
+	return stars >= f.Min && stars <= f.Max
+}
+
+// RankedOnlyFilter matches any score on an unranked leaderboard, so that
+// combined with other filters only ranked scores survive.
+type RankedOnlyFilter struct{}
+
+func (f RankedOnlyFilter) Name() string { return "rankedOnly" }
+
+func (f RankedOnlyFilter) Match(ps PlayerScore) bool {
+	return !ps.Leaderboard.Ranked
+}
+
+// AndFilter matches a score only if every sub-filter matches it.
+type AndFilter struct {
+	Filters []Filter
+}
+
+func (f AndFilter) Name() string { return "and" }
+
+func (f AndFilter) Match(ps PlayerScore) bool {
+	if len(f.Filters) == 0 {
+		return false
+	}
+	for _, sub := range f.Filters {
+		if !sub.Match(ps) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrFilter matches a score if any sub-filter matches it.
+type OrFilter struct {
+	Filters []Filter
+}
+
+func (f OrFilter) Name() string { return "or" }
+
+func (f OrFilter) Match(ps PlayerScore) bool {
+	for _, sub := range f.Filters {
+		if sub.Match(ps) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotFilter inverts a single sub-filter.
+type NotFilter struct {
+	Filter Filter
+}
+
+func (f NotFilter) Name() string { return "not" }
+
+func (f NotFilter) Match(ps PlayerScore) bool { return !f.Filter.Match(ps) }
@@ -0,0 +1,89 @@
+package ranker
+
+import (
+	"os"
+	"testing"
+)
+
+func rankedScore(stars float64, ranked bool) PlayerScore {
+	return PlayerScore{Leaderboard: Leaderboard{Stars: stars, Ranked: ranked}}
+}
+
+func TestStarRangeFilter(t *testing.T) {
+	f := StarRangeFilter{Min: 5, Max: 10}
+
+	if !f.Match(rankedScore(7, true)) {
+		t.Error("expected a 7-star score to match a [5,10] range")
+	}
+	if f.Match(rankedScore(2, true)) {
+		t.Error("did not expect a 2-star score to match a [5,10] range")
+	}
+}
+
+func TestRankedOnlyFilter(t *testing.T) {
+	f := RankedOnlyFilter{}
+
+	if f.Match(rankedScore(5, true)) {
+		t.Error("did not expect a ranked score to be excluded")
+	}
+	if !f.Match(rankedScore(5, false)) {
+		t.Error("expected an unranked score to be excluded")
+	}
+}
+
+func TestAndOrNotFilters(t *testing.T) {
+	lowStars := StarRangeFilter{Min: 0, Max: 3}
+	unranked := RankedOnlyFilter{}
+	score := rankedScore(2, false)
+
+	if !(AndFilter{Filters: []Filter{lowStars, unranked}}).Match(score) {
+		t.Error("AndFilter should match when every sub-filter matches")
+	}
+	if !(OrFilter{Filters: []Filter{lowStars, RankedOnlyFilter{}}}).Match(rankedScore(2, true)) {
+		t.Error("OrFilter should match when any sub-filter matches")
+	}
+	if (NotFilter{Filter: lowStars}).Match(rankedScore(2, true)) {
+		t.Error("NotFilter should invert its sub-filter's result")
+	}
+}
+
+func TestLoadFiltersYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/filters.yaml"
+	writeFile(t, path, `
+filters:
+  - type: mapperName
+    names: ["aquaflee", "someone-else"]
+  - type: starRange
+    minStars: 8
+    maxStars: 20
+`)
+
+	filters, err := LoadFilters(path)
+	if err != nil {
+		t.Fatalf("LoadFilters() error = %v", err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("len(filters) = %d, want 2", len(filters))
+	}
+	if filters[0].Name() != "mapperName" || filters[1].Name() != "starRange" {
+		t.Errorf("filters = %+v, want [mapperName starRange]", filters)
+	}
+}
+
+func TestLoadFiltersUnknownType(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/filters.json"
+	writeFile(t, path, `{"filters":[{"type":"madeUp"}]}`)
+
+	if _, err := LoadFilters(path); err == nil {
+		t.Fatal("expected an error for an unknown filter type")
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
@@ -0,0 +1,111 @@
+package ranker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FilterSpec is the on-disk representation of a single Filter, as loaded
+// from a -filters config file.
+type FilterSpec struct {
+	Type         string       `yaml:"type" json:"type"`
+	Names        []string     `yaml:"names,omitempty" json:"names,omitempty"`
+	Hashes       []string     `yaml:"hashes,omitempty" json:"hashes,omitempty"`
+	Difficulties []string     `yaml:"difficulties,omitempty" json:"difficulties,omitempty"`
+	MinStars     float64      `yaml:"minStars,omitempty" json:"minStars,omitempty"`
+	MaxStars     float64      `yaml:"maxStars,omitempty" json:"maxStars,omitempty"`
+	Filters      []FilterSpec `yaml:"filters,omitempty" json:"filters,omitempty"`
+	Filter       *FilterSpec  `yaml:"filter,omitempty" json:"filter,omitempty"`
+}
+
+// Build constructs the Filter described by this spec.
+func (s FilterSpec) Build() (Filter, error) {
+	switch s.Type {
+	case "mapperName":
+		return MapperNameFilter{Names: s.Names}, nil
+	case "songHash":
+		return SongHashFilter{Hashes: s.Hashes}, nil
+	case "difficulty":
+		return DifficultyFilter{Difficulties: s.Difficulties}, nil
+	case "starRange":
+		return StarRangeFilter{Min: s.MinStars, Max: s.MaxStars}, nil
+	case "rankedOnly":
+		return RankedOnlyFilter{}, nil
+	case "and":
+		sub, err := buildFilters(s.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return AndFilter{Filters: sub}, nil
+	case "or":
+		sub, err := buildFilters(s.Filters)
+		if err != nil {
+			return nil, err
+		}
+		return OrFilter{Filters: sub}, nil
+	case "not":
+		if s.Filter == nil {
+			return nil, fmt.Errorf("filter type %q requires a nested \"filter\"", s.Type)
+		}
+		sub, err := s.Filter.Build()
+		if err != nil {
+			return nil, err
+		}
+		return NotFilter{Filter: sub}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter type %q", s.Type)
+	}
+}
+
+func buildFilters(specs []FilterSpec) ([]Filter, error) {
+	filters := make([]Filter, 0, len(specs))
+	for _, spec := range specs {
+		f, err := spec.Build()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// FilterConfig is the top-level shape of a -filters config file.
+type FilterConfig struct {
+	Filters []FilterSpec `yaml:"filters" json:"filters"`
+}
+
+// LoadFilters reads a YAML or JSON filter config (format selected by file
+// extension) and builds the active Filter set.
+func LoadFilters(path string) ([]Filter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg FilterConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	default:
+		return nil, fmt.Errorf("unsupported filter config extension: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing filter config %s: %w", path, err)
+	}
+
+	return buildFilters(cfg.Filters)
+}
+
+// DefaultFilters returns the filter set used when no -filters config is
+// given, preserving the original hardcoded behavior of excluding aquaflee
+// maps.
+func DefaultFilters() []Filter {
+	return []Filter{MapperNameFilter{Names: []string{"aquaflee"}}}
+}
@@ -0,0 +1,46 @@
+package ranker
+
+import (
+	"math"
+	"sort"
+)
+
+// decayBase is ScoreSaber's weight decay between successive ranked PP
+// contributions: the Nth-best surviving score (0-indexed) counts for
+// decayBase^N of its raw PP. Summing pp*weight straight off the API is only
+// correct for a player's full score list; once any score is filtered out,
+// the remaining API-provided weights no longer reflect their new rank, so
+// the decay curve must be reapplied from scratch.
+const decayBase = 0.965
+
+// RecomputePP applies filters to scores, sorts the survivors by raw PP
+// descending, and re-weights them using ScoreSaber's decay curve. kept and
+// removed count the surviving and filtered-out scores respectively.
+func RecomputePP(scores []PlayerScore, filters []Filter) (total float64, kept, removed int) {
+	survivors := make([]PlayerScore, 0, len(scores))
+	for _, score := range scores {
+		excluded := false
+		for _, f := range filters {
+			if f.Match(score) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			removed++
+			continue
+		}
+		survivors = append(survivors, score)
+	}
+	kept = len(survivors)
+
+	sort.Slice(survivors, func(i, j int) bool {
+		return survivors[i].Score.PP > survivors[j].Score.PP
+	})
+
+	for i, score := range survivors {
+		total += score.Score.PP * math.Pow(decayBase, float64(i))
+	}
+
+	return total, kept, removed
+}
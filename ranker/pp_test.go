@@ -0,0 +1,81 @@
+package ranker
+
+import (
+	"math"
+	"testing"
+)
+
+func ppScore(pp float64, levelAuthor string) PlayerScore {
+	return PlayerScore{
+		Score:       Score{PP: pp},
+		Leaderboard: Leaderboard{LevelAuthorName: levelAuthor},
+	}
+}
+
+func TestRecomputePP(t *testing.T) {
+	tests := []struct {
+		name        string
+		scores      []PlayerScore
+		filters     []Filter
+		wantTotal   float64
+		wantKept    int
+		wantRemoved int
+	}{
+		{
+			name:        "no scores",
+			scores:      nil,
+			wantTotal:   0,
+			wantKept:    0,
+			wantRemoved: 0,
+		},
+		{
+			name: "no filters reproduces the 0.965 weighted total",
+			scores: []PlayerScore{
+				ppScore(500, "someone"),
+				ppScore(400, "someone"),
+				ppScore(300, "someone"),
+			},
+			wantTotal:   500 + 400*0.965 + 300*math.Pow(0.965, 2),
+			wantKept:    3,
+			wantRemoved: 0,
+		},
+		{
+			name: "filtered scores are excluded and the rest reweighted",
+			scores: []PlayerScore{
+				ppScore(500, "someone"),
+				ppScore(450, "aquaflee"),
+				ppScore(400, "someone"),
+			},
+			filters:     []Filter{MapperNameFilter{Names: []string{"aquaflee"}}},
+			wantTotal:   500 + 400*0.965,
+			wantKept:    2,
+			wantRemoved: 1,
+		},
+		{
+			name: "scores are re-sorted by PP before weighting",
+			scores: []PlayerScore{
+				ppScore(100, "someone"),
+				ppScore(300, "someone"),
+				ppScore(200, "someone"),
+			},
+			wantTotal:   300 + 200*0.965 + 100*math.Pow(0.965, 2),
+			wantKept:    3,
+			wantRemoved: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, kept, removed := RecomputePP(tt.scores, tt.filters)
+			if math.Abs(total-tt.wantTotal) > 0.01 {
+				t.Errorf("total = %v, want %v (within 0.01)", total, tt.wantTotal)
+			}
+			if kept != tt.wantKept {
+				t.Errorf("kept = %d, want %d", kept, tt.wantKept)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed = %d, want %d", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package ranker
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/KittenzExe/scoresaberwithoutaquaflee/metrics"
+)
+
+// scoreSaberRateLimit is ScoreSaber's documented request budget.
+const scoreSaberRateLimit = 400.0 / 60.0 // requests per second
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// Ranker recomputes ScoreSaber's top-N rankings with the active Filters
+// excluded.
+type Ranker struct {
+	Client  HTTPClient
+	Clock   Clock
+	BaseURL string
+	Limit   int
+
+	// Workers is the number of concurrent goroutines fetching player
+	// scores. Defaults to 1 if unset.
+	Workers int
+
+	// Limiter caps outbound requests to respect ScoreSaber's rate limit.
+	// A nil Limiter disables throttling.
+	Limiter *rate.Limiter
+
+	// Filters is the active set of score filters. A score is excluded from
+	// a player's recomputed PP if any filter matches it.
+	Filters []Filter
+}
+
+// New returns a Ranker configured with sane defaults. Callers can override
+// Client/Clock/BaseURL/Limit/Workers/Filters for tests or alternate
+// deployments.
+func New() *Ranker {
+	return &Ranker{
+		Client:  http.DefaultClient,
+		Clock:   realClock{},
+		BaseURL: DefaultBaseURL,
+		Limit:   50,
+		Workers: 8,
+		Limiter: rate.NewLimiter(rate.Limit(scoreSaberRateLimit), 10),
+		Filters: DefaultFilters(),
+	}
+}
+
+// Recompute fetches the current top players and recomputes their total PP
+// with the active Filters excluded, returning results sorted by the
+// recomputed PP descending with NewRank populated. Player scores are fetched
+// concurrently across a worker pool gated by Limiter.
+func (r *Ranker) Recompute(ctx context.Context) ([]PlayerResult, error) {
+	start := time.Now()
+	defer func() {
+		metrics.RecomputeDurationSeconds.Observe(time.Since(start).Seconds())
+	}()
+
+	players, err := FetchPlayers(ctx, r.Client, r.Limiter, r.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := players.Players
+	if r.Limit < len(candidates) {
+		candidates = candidates[:r.Limit]
+	}
+
+	workers := r.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan Player)
+	out := make(chan *PlayerResult, len(candidates))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for player := range jobs {
+				out <- r.fetchOne(ctx, player)
+			}
+		}()
+	}
+
+	go func() {
+		for _, player := range candidates {
+			jobs <- player
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	var results []PlayerResult
+	for result := range out {
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].TotalPP > results[j].TotalPP
+	})
+	for i := range results {
+		results[i].NewRank = i + 1
+	}
+
+	return results, nil
+}
+
+// fetchOne fetches a single player's scores, retrying transient failures. It
+// returns nil if the player could not be fetched after all retries. Each
+// outbound request (one per page of scores) is individually gated by
+// r.Limiter inside FetchPlayerScores.
+func (r *Ranker) fetchOne(ctx context.Context, player Player) *PlayerResult {
+	start := time.Now()
+
+	var fetched ScoreFetchResult
+	err := withRetry(ctx, func() error {
+		var err error
+		fetched, err = FetchPlayerScores(ctx, r.Client, r.Limiter, r.BaseURL, player.ID, r.Filters)
+		return err
+	})
+	duration := time.Since(start)
+	if err != nil {
+		slog.Error("fetch player scores failed", "player_id", player.ID, "rank", player.Rank, "duration_ms", duration.Milliseconds(), "http_status", fetched.HTTPStatus, "error", err)
+		return nil
+	}
+
+	filterHits := 0
+	for _, count := range fetched.FilteredScores {
+		filterHits += count
+	}
+
+	result := &PlayerResult{
+		Player:         player,
+		OriginalRank:   player.Rank,
+		TotalPP:        fetched.TotalPP,
+		TotalScores:    fetched.TotalScores,
+		ValidScores:    fetched.TotalScores - fetched.RemovedScores,
+		PPDifference:   player.PP - fetched.TotalPP,
+		FilteredScores: fetched.FilteredScores,
+		Scores:         fetched.Scores,
+	}
+
+	metrics.PlayerPPDelta.WithLabelValues(player.ID).Set(result.PPDifference)
+	slog.Info("recomputed player", "player_id", player.ID, "rank", player.Rank, "duration_ms", duration.Milliseconds(), "http_status", fetched.HTTPStatus, "filter_hits", filterHits)
+
+	return result
+}
@@ -0,0 +1,67 @@
+package ranker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c fakeClock) Now() time.Time { return c.now }
+
+func TestRecompute(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/players", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PlayersResponse{
+			Players: []Player{
+				{ID: "1", Name: "alice", Rank: 1, PP: 100},
+				{ID: "2", Name: "bob", Rank: 2, PP: 90},
+			},
+		})
+	})
+	mux.HandleFunc("/api/player/1/scores", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PlayerScores{PlayerScores: []PlayerScore{
+			{Score: Score{PP: 50, Weight: 1}, Leaderboard: Leaderboard{LevelAuthorName: "someone"}},
+			{Score: Score{PP: 50, Weight: 1}, Leaderboard: Leaderboard{LevelAuthorName: "aquaflee"}},
+		}})
+	})
+	mux.HandleFunc("/api/player/2/scores", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(PlayerScores{PlayerScores: []PlayerScore{
+			{Score: Score{PP: 95, Weight: 1}, Leaderboard: Leaderboard{LevelAuthorName: "someone"}},
+		}})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	rk := &Ranker{
+		Client:  srv.Client(),
+		Clock:   fakeClock{now: time.Unix(0, 0)},
+		BaseURL: srv.URL,
+		Limit:   50,
+		Workers: 4,
+		Filters: DefaultFilters(),
+	}
+
+	results, err := rk.Recompute(context.Background())
+	if err != nil {
+		t.Fatalf("Recompute() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+
+	if results[0].Player.ID != "2" || results[0].NewRank != 1 {
+		t.Errorf("results[0] = %+v, want bob ranked #1 (higher PP once aquaflee scores are excluded)", results[0])
+	}
+	if results[0].TotalPP != 95 {
+		t.Errorf("results[0].TotalPP = %v, want 95", results[0].TotalPP)
+	}
+	if results[1].FilteredScores["mapperName"] != 1 {
+		t.Errorf("results[1].FilteredScores[mapperName] = %d, want 1", results[1].FilteredScores["mapperName"])
+	}
+}
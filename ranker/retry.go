@@ -0,0 +1,38 @@
+package ranker
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+const (
+	maxAttempts  = 3
+	retryBaseDur = 200 * time.Millisecond
+)
+
+// withRetry calls fn up to maxAttempts times, backing off exponentially with
+// jitter between attempts. It returns fn's last error if every attempt
+// fails, or nil on the first success.
+func withRetry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		backoff := retryBaseDur * time.Duration(1<<attempt)
+		backoff += time.Duration(rand.Int63n(int64(retryBaseDur)))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+	return err
+}
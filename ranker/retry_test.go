@@ -0,0 +1,39 @@
+package ranker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithRetrySucceedsAfterFailures(t *testing.T) {
+	attempts := 0
+	err := withRetry(context.Background(), func() error {
+		attempts++
+		if attempts < maxAttempts {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withRetry() error = %v, want nil", err)
+	}
+	if attempts != maxAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, maxAttempts)
+	}
+}
+
+func TestWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	wantErr := errors.New("permanent")
+	err := withRetry(context.Background(), func() error {
+		attempts++
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("withRetry() error = %v, want %v", err, wantErr)
+	}
+	if attempts != maxAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, maxAttempts)
+	}
+}
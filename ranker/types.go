@@ -0,0 +1,116 @@
+// Package ranker fetches ScoreSaber player and score data and recomputes
+// rankings with the "aquaflee" maps excluded.
+package ranker
+
+type PlayersResponse struct {
+	Players []Player `json:"players"`
+}
+
+type Player struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	ProfilePicture string      `json:"profilePicture"`
+	Bio            *string     `json:"bio"`
+	Country        string      `json:"country"`
+	PP             float64     `json:"pp"`
+	Rank           int         `json:"rank"`
+	CountryRank    int         `json:"countryRank"`
+	Role           *string     `json:"role"`
+	Badges         interface{} `json:"badges"`
+	Histories      string      `json:"histories"`
+	Permissions    int         `json:"permissions"`
+	Banned         bool        `json:"banned"`
+	Inactive       bool        `json:"inactive"`
+	ScoreStats     ScoreStats  `json:"scoreStats"`
+	FirstSeen      string      `json:"firstSeen"`
+}
+
+type ScoreStats struct {
+	TotalScore            int     `json:"totalScore"`
+	TotalRankedScore      int     `json:"totalRankedScore"`
+	AverageRankedAccuracy float64 `json:"averageRankedAccuracy"`
+	TotalPlayCount        int     `json:"totalPlayCount"`
+	RankedPlayCount       int     `json:"rankedPlayCount"`
+	ReplaysWatched        int     `json:"replaysWatched"`
+}
+
+type PlayerScores struct {
+	PlayerScores []PlayerScore `json:"playerScores"`
+}
+
+type PlayerScore struct {
+	Score       Score       `json:"score"`
+	Leaderboard Leaderboard `json:"leaderboard"`
+}
+
+type Score struct {
+	ID                    int     `json:"id"`
+	LeaderboardPlayerInfo *string `json:"leaderboardPlayerInfo"`
+	Rank                  int     `json:"rank"`
+	BaseScore             int     `json:"baseScore"`
+	ModifiedScore         int     `json:"modifiedScore"`
+	PP                    float64 `json:"pp"`
+	Weight                float64 `json:"weight"`
+	Modifiers             string  `json:"modifiers"`
+	Multiplier            float64 `json:"multiplier"`
+	BadCuts               int     `json:"badCuts"`
+	MissedNotes           int     `json:"missedNotes"`
+	MaxCombo              int     `json:"maxCombo"`
+	FullCombo             bool    `json:"fullCombo"`
+	Hmd                   int     `json:"hmd"`
+	TimeSet               string  `json:"timeSet"`
+	HasReplay             bool    `json:"hasReplay"`
+	DeviceHmd             string  `json:"deviceHmd"`
+	DeviceControllerLeft  string  `json:"deviceControllerLeft"`
+	DeviceControllerRight string  `json:"deviceControllerRight"`
+}
+
+type Leaderboard struct {
+	ID                int        `json:"id"`
+	SongHash          string     `json:"songHash"`
+	SongName          string     `json:"songName"`
+	SongSubName       string     `json:"songSubName"`
+	SongAuthorName    string     `json:"songAuthorName"`
+	LevelAuthorName   string     `json:"levelAuthorName"`
+	Difficulty        Difficulty `json:"difficulty"`
+	MaxScore          int        `json:"maxScore"`
+	CreatedDate       string     `json:"createdDate"`
+	RankedDate        *string    `json:"rankedDate"`
+	QualifiedDate     *string    `json:"qualifiedDate"`
+	LovedDate         *string    `json:"lovedDate"`
+	Ranked            bool       `json:"ranked"`
+	Qualified         bool       `json:"qualified"`
+	Loved             bool       `json:"loved"`
+	MaxPP             float64    `json:"maxPP"`
+	Stars             float64    `json:"stars"`
+	Plays             int        `json:"plays"`
+	DailyPlays        int        `json:"dailyPlays"`
+	PositiveModifiers bool       `json:"positiveModifiers"`
+	PlayerScore       *string    `json:"playerScore"`
+	CoverImage        string     `json:"coverImage"`
+	Difficulties      *string    `json:"difficulties"`
+}
+
+type Difficulty struct {
+	LeaderboardID int    `json:"leaderboardId"`
+	Difficulty    int    `json:"difficulty"`
+	GameMode      string `json:"gameMode"`
+	DifficultyRaw string `json:"difficultyRaw"`
+}
+
+// PlayerResult is a single player's recomputed ranking for one cycle.
+type PlayerResult struct {
+	Player         Player         `json:"player"`
+	OriginalRank   int            `json:"originalRank"`
+	NewRank        int            `json:"newRank"`
+	TotalPP        float64        `json:"totalPP"`
+	TotalScores    int            `json:"totalScores"`
+	ValidScores    int            `json:"validScores"`
+	PPDifference   float64        `json:"ppDifference"`
+	FilteredScores map[string]int `json:"filteredScores"`
+
+	// Scores is every score fetched for this player this cycle, unfiltered.
+	// It is not serialized in API responses; it exists for callers (such as
+	// the store package) that persist per-leaderboard PP history.
+	Scores []PlayerScore `json:"-"`
+}
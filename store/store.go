@@ -0,0 +1,159 @@
+// Package store persists recompute cycles to SQLite so day-over-day rank
+// movement can be tracked across process restarts, not just within a single
+// recompute.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/KittenzExe/scoresaberwithoutaquaflee/ranker"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	taken_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS player_snapshots (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots(id),
+	player_id TEXT NOT NULL,
+	official_pp REAL NOT NULL,
+	recomputed_pp REAL NOT NULL,
+	official_rank INTEGER NOT NULL,
+	recomputed_rank INTEGER NOT NULL,
+	filtered_count INTEGER NOT NULL,
+	PRIMARY KEY (snapshot_id, player_id)
+);
+CREATE INDEX IF NOT EXISTS idx_player_snapshots_player_id ON player_snapshots(player_id);
+
+CREATE TABLE IF NOT EXISTS scores (
+	player_id TEXT NOT NULL,
+	leaderboard_id INTEGER NOT NULL,
+	pp REAL NOT NULL,
+	seen_at DATETIME NOT NULL,
+	PRIMARY KEY (player_id, leaderboard_id)
+);
+`
+
+// Store wraps a SQLite database holding recompute history.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at path and runs
+// its migrations.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveSnapshot persists one recompute cycle: a snapshots row, a
+// player_snapshots row per result, and the latest PP seen for every score
+// fetched this cycle.
+func (s *Store) SaveSnapshot(ctx context.Context, takenAt time.Time, results []ranker.PlayerResult) (snapshotID int64, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO snapshots (taken_at) VALUES (?)`, takenAt)
+	if err != nil {
+		return 0, err
+	}
+	snapshotID, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, result := range results {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO player_snapshots
+				(snapshot_id, player_id, official_pp, recomputed_pp, official_rank, recomputed_rank, filtered_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			snapshotID, result.Player.ID, result.Player.PP, result.TotalPP,
+			result.OriginalRank, result.NewRank, result.TotalScores-result.ValidScores)
+		if err != nil {
+			return 0, fmt.Errorf("saving player_snapshots for %s: %w", result.Player.ID, err)
+		}
+
+		for _, score := range result.Scores {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO scores (player_id, leaderboard_id, pp, seen_at)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT (player_id, leaderboard_id) DO UPDATE SET pp = excluded.pp, seen_at = excluded.seen_at`,
+				result.Player.ID, score.Leaderboard.ID, score.Score.PP, takenAt)
+			if err != nil {
+				return 0, fmt.Errorf("saving scores for %s: %w", result.Player.ID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return snapshotID, nil
+}
+
+// HistoryPoint is one snapshot's worth of a player's recomputed ranking,
+// plus how its recomputed rank moved since the previous point in the
+// series.
+type HistoryPoint struct {
+	TakenAt        time.Time `json:"takenAt"`
+	OfficialPP     float64   `json:"officialPP"`
+	RecomputedPP   float64   `json:"recomputedPP"`
+	OfficialRank   int       `json:"officialRank"`
+	RecomputedRank int       `json:"recomputedRank"`
+	FilteredCount  int       `json:"filteredCount"`
+	RankDelta      int       `json:"rankDelta"`
+}
+
+// History returns a player's recomputed-rank time series since the given
+// time, oldest first, with RankDelta populated relative to the previous
+// point (0 for the first point in the series).
+func (s *Store) History(ctx context.Context, playerID string, since time.Time) ([]HistoryPoint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.taken_at, ps.official_pp, ps.recomputed_pp, ps.official_rank, ps.recomputed_rank, ps.filtered_count
+		FROM player_snapshots ps
+		JOIN snapshots s ON s.id = ps.snapshot_id
+		WHERE ps.player_id = ? AND s.taken_at >= ?
+		ORDER BY s.taken_at ASC`, playerID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []HistoryPoint
+	for rows.Next() {
+		var p HistoryPoint
+		if err := rows.Scan(&p.TakenAt, &p.OfficialPP, &p.RecomputedPP, &p.OfficialRank, &p.RecomputedRank, &p.FilteredCount); err != nil {
+			return nil, err
+		}
+		if len(points) > 0 {
+			p.RankDelta = points[len(points)-1].RecomputedRank - p.RecomputedRank
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
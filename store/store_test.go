@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KittenzExe/scoresaberwithoutaquaflee/ranker"
+)
+
+func TestSaveSnapshotAndHistory(t *testing.T) {
+	s, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	base := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	cycles := []struct {
+		takenAt time.Time
+		rank    int
+	}{
+		{base, 3},
+		{base.Add(24 * time.Hour), 1},
+	}
+
+	for _, c := range cycles {
+		results := []ranker.PlayerResult{{
+			Player:  ranker.Player{ID: "abc", PP: 100},
+			NewRank: c.rank,
+			TotalPP: 100,
+		}}
+		if _, err := s.SaveSnapshot(ctx, c.takenAt, results); err != nil {
+			t.Fatalf("SaveSnapshot() error = %v", err)
+		}
+	}
+
+	points, err := s.History(ctx, "abc", base.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("len(points) = %d, want 2", len(points))
+	}
+	if points[0].RankDelta != 0 {
+		t.Errorf("points[0].RankDelta = %d, want 0 (no prior snapshot)", points[0].RankDelta)
+	}
+	if points[1].RankDelta != 2 {
+		t.Errorf("points[1].RankDelta = %d, want 2 (moved from #3 to #1)", points[1].RankDelta)
+	}
+}